@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+var audioVoice string
+var audioSpeed float64
+var audioFormat string
+
+var transcriptionFormat string
+var transcriptionTimestampGranularities string
+var transcriptionTranslate bool
+var transcriptionLanguage string
+var transcriptionPrompt string
+
+func audioGeneration(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	text := args[0]
+	outputFile := args[1]
+
+	resp, err := client.CreateSpeech(context.Background(), openai.CreateSpeechRequest{
+		Model:          openai.SpeechModel(modelFor(cmd, "", string(openai.TTSModel1))),
+		Input:          text,
+		Voice:          openai.SpeechVoice(audioVoice),
+		ResponseFormat: openai.SpeechResponseFormat(audioFormat),
+		Speed:          audioSpeed,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Close()
+
+	out, err := os.Create(outputFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Audio saved to %s\n", outputFile)
+}
+
+func audioTranscription(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	filename := args[0]
+
+	req := openai.AudioRequest{
+		FilePath:               filename,
+		Model:                  modelFor(cmd, "", openai.Whisper1),
+		Prompt:                 transcriptionPrompt,
+		Temperature:            0.5,
+		Format:                 openai.AudioResponseFormat(transcriptionFormat),
+		TimestampGranularities: parseTimestampGranularities(transcriptionTimestampGranularities),
+	}
+
+	if transcriptionTranslate {
+		// Language only applies to transcription; the translation endpoint
+		// always produces English output regardless of source language.
+		result, err := client.CreateTranslation(context.Background(), req)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(result.Text)
+		return
+	}
+
+	req.Language = transcriptionLanguage
+	result, err := client.CreateTranscription(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(result.Text)
+}
+
+// parseTimestampGranularities turns a comma-separated --timestamp-granularities
+// value (e.g. "segment,word") into the granularities the API expects.
+func parseTimestampGranularities(raw string) []openai.TranscriptionTimestampGranularity {
+	if raw == "" {
+		return nil
+	}
+
+	var granularities []openai.TranscriptionTimestampGranularity
+	for _, g := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(g) {
+		case "segment":
+			granularities = append(granularities, openai.TranscriptionTimestampGranularitySegment)
+		case "word":
+			granularities = append(granularities, openai.TranscriptionTimestampGranularityWord)
+		}
+	}
+	return granularities
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"net/http"
+	"os"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+var dialogueModel string
+var dialogueSystem string
+var dialogueResetHistory bool
+var dialogueTools string
+var dialogueMaxToolIterations int
+var dialogueAllowBuiltinTools bool
+var dialogueYes bool
+
+var codeGenerationModel string
+var codeGenerationSystem string
+
+var imageRecognitionModel string
+var imageRecognitionPrompt string
+
+// maxImageBytes is the size above which an image is downscaled before being
+// inlined as a data URL, keeping well clear of typical API payload limits.
+const maxImageBytes = 20 * 1024 * 1024
+
+func dialogue(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	prompt := args[0]
+
+	messages, err := loadHistory()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if dialogueResetHistory {
+		messages = nil
+	}
+	if len(messages) == 0 && dialogueSystem != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: dialogueSystem,
+		})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: prompt,
+	})
+
+	model := modelFor(cmd, dialogueModel, "gpt-4o")
+
+	var registry *toolRegistry
+	var tools []openai.Tool
+	if dialogueTools != "" || dialogueAllowBuiltinTools {
+		registry = newToolRegistry(dialogueAllowBuiltinTools)
+		if dialogueTools != "" {
+			if err := registry.loadManifest(dialogueTools); err != nil {
+				log.Fatal(err)
+			}
+		}
+		tools = registry.defs
+	}
+
+	for iteration := 0; ; iteration++ {
+		if registry != nil && iteration >= dialogueMaxToolIterations {
+			log.Fatalf("exceeded --max-tool-iterations (%d) without a final answer", dialogueMaxToolIterations)
+		}
+
+		req := openai.ChatCompletionRequest{
+			Model:    model,
+			Messages: messages,
+			Tools:    tools,
+		}
+
+		// Streaming only applies to the turn that produces the final
+		// answer; a turn that might still hand off to a tool call is
+		// always fetched in full so we can inspect it for ToolCalls.
+		if dialogueStream && registry == nil {
+			req.Stream = true
+			reply, err := streamChatCompletion(client, req)
+			if err != nil {
+				log.Fatal(err)
+			}
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: reply,
+			})
+			break
+		}
+
+		completion, err := client.CreateChatCompletion(context.Background(), req)
+		if err != nil {
+			log.Fatal(err)
+		}
+		message := completion.Choices[0].Message
+
+		if len(message.ToolCalls) == 0 {
+			fmt.Println(message.Content)
+			messages = append(messages, message)
+			break
+		}
+
+		messages = append(messages, message)
+		for _, call := range message.ToolCalls {
+			var result string
+			if !dialogueYes && !confirmToolCall(call.Function.Name, call.Function.Arguments) {
+				result = "error: tool call declined by user"
+			} else if out, err := registry.call(call.Function.Name, call.Function.Arguments); err != nil {
+				result = fmt.Sprintf("error: %v", err)
+			} else {
+				result = out
+			}
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	if err := saveHistory(messages); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func codeGeneration(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	prompt := args[0]
+
+	var messages []openai.ChatCompletionMessage
+	if codeGenerationSystem != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: codeGenerationSystem,
+		})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: prompt,
+	})
+
+	req := openai.ChatCompletionRequest{
+		Model:    modelFor(cmd, codeGenerationModel, "gpt-4o"),
+		Messages: messages,
+	}
+
+	if codeGenerationStream {
+		req.Stream = true
+		if _, err := streamChatCompletion(client, req); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	result, err := client.CreateChatCompletion(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(result.Choices[0].Message.Content)
+}
+
+func imageRecognition(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	filename := args[0]
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(data) > maxImageBytes {
+		data, err = downscaleToFit(data, maxImageBytes)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	mimeType := http.DetectContentType(data)
+	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+
+	req := openai.ChatCompletionRequest{
+		Model: modelFor(cmd, imageRecognitionModel, "gpt-4o"),
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleUser,
+				MultiContent: []openai.ChatMessagePart{
+					{
+						Type: openai.ChatMessagePartTypeText,
+						Text: imageRecognitionPrompt,
+					},
+					{
+						Type: openai.ChatMessagePartTypeImageURL,
+						ImageURL: &openai.ChatMessageImageURL{
+							URL: dataURL,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := client.CreateChatCompletion(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(result.Choices[0].Message.Content)
+}
+
+// downscaleToFit re-encodes an image as PNG at progressively lower
+// resolution until it fits within budget bytes, so oversized photos can
+// still be inlined as a data URL instead of being rejected by the API.
+func downscaleToFit(data []byte, budget int) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	scale := math.Sqrt(float64(budget) / float64(len(data)))
+	bounds := img.Bounds()
+	const maxAttempts = 6
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		w := int(float64(bounds.Dx()) * scale)
+		h := int(float64(bounds.Dy()) * scale)
+		if w < 1 {
+			w = 1
+		}
+		if h < 1 {
+			h = 1
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, resizeNearestNeighbor(img, w, h)); err != nil {
+			return nil, err
+		}
+		if buf.Len() <= budget {
+			return buf.Bytes(), nil
+		}
+		scale *= 0.7
+	}
+	return nil, fmt.Errorf("could not downscale image under %d bytes", budget)
+}
+
+// resizeNearestNeighbor scales img to the given dimensions using nearest
+// neighbor sampling, avoiding a dependency on an external imaging library.
+func resizeNearestNeighbor(img image.Image, w, h int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		srcY := bounds.Min.Y + y*srcH/h
+		for x := 0; x < w; x++ {
+			srcX := bounds.Min.X + x*srcW/w
+			dst.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+	return dst
+}
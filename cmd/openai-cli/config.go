@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// providerConfig holds the per-command routing overrides a user can set in
+// ~/.openai-cli/config.yaml, letting each subcommand talk to a different
+// OpenAI-compatible endpoint (OpenAI itself, LocalAI, Ollama, vLLM, ...).
+type providerConfig struct {
+	BaseURL string `yaml:"base_url,omitempty"`
+	APIKey  string `yaml:"api_key,omitempty"`
+	Model   string `yaml:"model,omitempty"`
+}
+
+// cliConfig is the root of config.yaml: one providerConfig per command name.
+type cliConfig struct {
+	Providers map[string]providerConfig `yaml:"providers"`
+}
+
+func configFilePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// loadConfig reads config.yaml, returning an empty config if it does not
+// exist yet.
+func loadConfig() (*cliConfig, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &cliConfig{Providers: map[string]providerConfig{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg cliConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Providers == nil {
+		cfg.Providers = map[string]providerConfig{}
+	}
+	return &cfg, nil
+}
+
+func saveConfig(cfg *cliConfig) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// resolveProvider looks up the config.yaml entry for cmd's own name,
+// returning a zero-value providerConfig if none is set.
+func resolveProvider(cmd *cobra.Command) providerConfig {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cfg.Providers[cmd.Name()]
+}
+
+// modelFor resolves which model cmd should use: an explicit --model flag
+// wins, then the command's provider entry in config.yaml, then def.
+func modelFor(cmd *cobra.Command, flagValue, def string) string {
+	if cmd.Flags().Changed("model") {
+		return flagValue
+	}
+	if provider := resolveProvider(cmd); provider.Model != "" {
+		return provider.Model
+	}
+	if flagValue != "" {
+		return flagValue
+	}
+	return def
+}
+
+var providerBaseURL string
+var providerAPIKey string
+var providerModel string
+
+func providersList(cmd *cobra.Command, args []string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(cfg.Providers) == 0 {
+		fmt.Println("No providers configured.")
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := cfg.Providers[name]
+		fmt.Printf("%s:\n  base_url: %s\n  model: %s\n", name, p.BaseURL, p.Model)
+	}
+}
+
+func providersSet(cmd *cobra.Command, args []string) {
+	command := args[0]
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	p := cfg.Providers[command]
+	if providerBaseURL != "" {
+		p.BaseURL = providerBaseURL
+	}
+	if providerAPIKey != "" {
+		p.APIKey = providerAPIKey
+	}
+	if providerModel != "" {
+		p.Model = providerModel
+	}
+	cfg.Providers[command] = p
+
+	if err := saveConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Saved provider settings for %q\n", command)
+}
+
+func providersRemove(cmd *cobra.Command, args []string) {
+	command := args[0]
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	delete(cfg.Providers, command)
+
+	if err := saveConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Removed provider settings for %q\n", command)
+}
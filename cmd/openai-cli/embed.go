@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+var embedModel string
+var embedDim int
+var embedStore string
+var embedChunkSize int
+var embedChunkOverlap int
+
+var askModel string
+var askDim int
+var askStore string
+var askTopK int
+
+// maxEmbeddingBatch is the largest input array the embeddings endpoint
+// accepts in one request.
+const maxEmbeddingBatch = 2048
+
+// textChunk is one slice of a source file, keyed by its token offset so the
+// same file can be re-embedded idempotently.
+type textChunk struct {
+	Path   string
+	Offset int
+	Text   string
+}
+
+func resolveStorePath(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vectors.db"), nil
+}
+
+// openStore opens (creating if necessary) the SQLite-backed vector store.
+func openStore(flagValue string) (*sql.DB, error) {
+	path, err := resolveStorePath(flagValue)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS chunks (
+		path      TEXT    NOT NULL,
+		offset    INTEGER NOT NULL,
+		text      TEXT    NOT NULL,
+		model     TEXT    NOT NULL,
+		embedding TEXT    NOT NULL,
+		PRIMARY KEY (path, offset)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// storeChunk persists c's embedding along with the model that produced it,
+// so a later ask with a different --model/--dim can tell its query vector
+// isn't comparable to this row instead of indexing past its end.
+func storeChunk(db *sql.DB, c textChunk, model string, embedding []float32) error {
+	encoded, err := json.Marshal(embedding)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		`INSERT INTO chunks (path, offset, text, model, embedding) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(path, offset) DO UPDATE SET text = excluded.text, model = excluded.model, embedding = excluded.embedding`,
+		c.Path, c.Offset, c.Text, model, string(encoded),
+	)
+	return err
+}
+
+// collectTextFiles expands root into a list of files: itself if it's a
+// file, or every file beneath it if it's a directory, skipping dot-directories
+// (.git, .svn, ...) and extensions that are never text so pointing embed at a
+// repo root doesn't burn API quota embedding pack files and binaries.
+func collectTextFiles(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if looksLikeText(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// nonTextExtensions are file extensions collectTextFiles skips outright:
+// images, archives, audio/video, and compiled binaries aren't worth tokenizing
+// and embedding as RAG context.
+var nonTextExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".ico": true, ".webp": true,
+	".pdf": true, ".zip": true, ".tar": true, ".gz": true, ".bz2": true, ".xz": true, ".7z": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".bin": true, ".o": true, ".a": true,
+	".mp3": true, ".mp4": true, ".mov": true, ".avi": true, ".wav": true, ".flac": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true,
+}
+
+func looksLikeText(path string) bool {
+	return !nonTextExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// chunkText splits text into overlapping windows of size tokens, using enc
+// so chunk boundaries line up with what the embedding model actually sees.
+func chunkText(enc *tiktoken.Tiktoken, path, text string, size, overlap int) []textChunk {
+	tokens := enc.Encode(text, nil, nil)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	step := size - overlap
+	if step <= 0 {
+		step = size
+	}
+
+	var chunks []textChunk
+	for offset := 0; offset < len(tokens); offset += step {
+		end := offset + size
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		chunks = append(chunks, textChunk{
+			Path:   path,
+			Offset: offset,
+			Text:   enc.Decode(tokens[offset:end]),
+		})
+		if end == len(tokens) {
+			break
+		}
+	}
+	return chunks
+}
+
+func embedFiles(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	root := args[0]
+	model := modelFor(cmd, embedModel, "text-embedding-3-small")
+
+	files, err := collectTextFiles(root)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding("cl100k_base")
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var chunks []textChunk
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		chunks = append(chunks, chunkText(enc, path, string(data), embedChunkSize, embedChunkOverlap)...)
+	}
+	fmt.Printf("Chunked %d file(s) into %d chunk(s)\n", len(files), len(chunks))
+
+	db, err := openStore(embedStore)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	for start := 0; start < len(chunks); start += maxEmbeddingBatch {
+		end := start + maxEmbeddingBatch
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		batch := chunks[start:end]
+
+		inputs := make([]string, len(batch))
+		for i, c := range batch {
+			inputs[i] = c.Text
+		}
+
+		resp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequestStrings{
+			Input:      inputs,
+			Model:      openai.EmbeddingModel(model),
+			Dimensions: embedDim,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for i, data := range resp.Data {
+			if err := storeChunk(db, batch[i], model, data.Embedding); err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Printf("Embedded %d/%d chunk(s)\n", end, len(chunks))
+	}
+}
+
+// cosineSimilarity returns 0 for vectors of unequal length rather than
+// panicking, since a and b may come from different embedding models/--dim.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func ask(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	question := args[0]
+
+	db, err := openStore(askStore)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT path, offset, text, model, embedding FROM chunks`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	model := modelFor(cmd, askModel, "text-embedding-3-small")
+	embedResp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequestStrings{
+		Input:      []string{question},
+		Model:      openai.EmbeddingModel(model),
+		Dimensions: askDim,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	queryEmbedding := embedResp.Data[0].Embedding
+
+	type scoredChunk struct {
+		textChunk
+		score float64
+	}
+	var candidates []scoredChunk
+	var skipped int
+	for rows.Next() {
+		var c textChunk
+		var rowModel, embeddingJSON string
+		if err := rows.Scan(&c.Path, &c.Offset, &c.Text, &rowModel, &embeddingJSON); err != nil {
+			log.Fatal(err)
+		}
+		var embedding []float32
+		if err := json.Unmarshal([]byte(embeddingJSON), &embedding); err != nil {
+			log.Fatal(err)
+		}
+		if rowModel != model || len(embedding) != len(queryEmbedding) {
+			skipped++
+			continue
+		}
+		candidates = append(candidates, scoredChunk{textChunk: c, score: cosineSimilarity(queryEmbedding, embedding)})
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d chunk(s) embedded with a different model/--dim than %q\n", skipped, model)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > askTopK {
+		candidates = candidates[:askTopK]
+	}
+
+	var retrieved strings.Builder
+	for _, c := range candidates {
+		fmt.Fprintf(&retrieved, "--- %s (offset %d) ---\n%s\n\n", c.Path, c.Offset, c.Text)
+	}
+
+	completion, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "gpt-4o",
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: "Answer the question using only the provided context. If the answer isn't in the context, say so.",
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("Context:\n%s\nQuestion: %s", retrieved.String(), question),
+			},
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(completion.Choices[0].Message.Content)
+}
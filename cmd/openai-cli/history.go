@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const historyFileName = "history.json"
+
+// configDir returns the directory the CLI stores its local state in,
+// creating it if it does not already exist.
+func configDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".openai-cli")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// loadHistory reads the persisted `dialogue` conversation, returning nil if
+// none has been saved yet.
+func loadHistory() ([]openai.ChatCompletionMessage, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, historyFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []openai.ChatCompletionMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// saveHistory persists the full conversation so the next `dialogue`
+// invocation can continue it.
+func saveHistory(messages []openai.ChatCompletionMessage) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, historyFileName), data, 0600)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+var imageGenModel string
+var imageGenSize string
+var imageGenQuality string
+var imageGenStyle string
+var imageGenN int
+var imageGenResponseFormat string
+
+var imageEditMask string
+
+var dalle2Sizes = map[string]bool{"256x256": true, "512x512": true, "1024x1024": true}
+var dalle3Sizes = map[string]bool{"1024x1024": true, "1792x1024": true, "1024x1792": true}
+
+// validateImageSize rejects --size values the chosen model doesn't support,
+// rather than letting the API reject them after a round trip.
+func validateImageSize(model, size string) error {
+	if model == "dall-e-3" {
+		if !dalle3Sizes[size] {
+			return fmt.Errorf("invalid --size %q for dall-e-3, expected one of 1024x1024, 1792x1024, 1024x1792", size)
+		}
+		return nil
+	}
+	if !dalle2Sizes[size] {
+		return fmt.Errorf("invalid --size %q for dall-e-2, expected one of 256x256, 512x512, 1024x1024", size)
+	}
+	return nil
+}
+
+func imageGeneration(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	prompt := args[0]
+
+	model := modelFor(cmd, imageGenModel, "dall-e-2")
+	if err := validateImageSize(model, imageGenSize); err != nil {
+		log.Fatal(err)
+	}
+
+	req := openai.ImageRequest{
+		Prompt:         prompt,
+		Model:          model,
+		N:              imageGenN,
+		Size:           imageGenSize,
+		ResponseFormat: imageGenResponseFormat,
+		User:           "Developer",
+	}
+	if model == "dall-e-3" {
+		req.Quality = imageGenQuality
+		req.Style = imageGenStyle
+	}
+
+	result, err := client.CreateImage(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(result.Data) == 0 {
+		log.Fatal("no result, no image data")
+	}
+
+	for i, item := range result.Data {
+		outputFile := "output.png"
+		if len(result.Data) > 1 {
+			outputFile = fmt.Sprintf("output-%d.png", i+1)
+		}
+		if err := saveImageData(item, outputFile); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Image saved to %s\n", outputFile)
+	}
+}
+
+func imageEditing(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	inputFile := args[0]
+	instructions := args[1]
+	outputFile := args[2]
+
+	inputData, err := os.Open(inputFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer inputData.Close()
+
+	req := openai.ImageEditRequest{
+		Image:  inputData,
+		Prompt: instructions,
+		N:      1,
+		Size:   openai.CreateImageSize256x256,
+	}
+
+	if imageEditMask != "" {
+		mask, err := os.Open(imageEditMask)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mask.Close()
+		req.Mask = mask
+	}
+
+	result, err := client.CreateEditImage(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(result.Data) == 0 {
+		log.Fatal("no result, no image edited")
+	}
+
+	if err := saveImageData(result.Data[0], outputFile); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Image saved to %s\n", outputFile)
+}
+
+func imageVariation(cmd *cobra.Command, args []string) {
+	client := getClient(cmd)
+	inputFile := args[0]
+
+	inputData, err := os.Open(inputFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer inputData.Close()
+
+	result, err := client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+		Image:          inputData,
+		N:              1,
+		Size:           openai.CreateImageSize256x256,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(result.Data) == 0 {
+		log.Fatal("no result, no image variation")
+	}
+
+	const outputFile = "output.png"
+	if err := saveImageData(result.Data[0], outputFile); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Image saved to %s\n", outputFile)
+}
+
+// saveImageData writes one image response item to outputFile, downloading
+// it when the API returned a URL instead of inline base64 data.
+func saveImageData(item openai.ImageResponseDataInner, outputFile string) error {
+	if item.URL != "" {
+		return downloadWithProgress(item.URL, outputFile)
+	}
+
+	imageBytes, err := base64.StdEncoding.DecodeString(item.B64JSON)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputFile, imageBytes, 0644)
+}
+
+// downloadWithProgress streams url to outputFile, printing a percentage
+// progress bar as bytes arrive.
+func downloadWithProgress(url, outputFile string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	pw := &progressWriter{label: outputFile, total: resp.ContentLength}
+	if _, err := io.Copy(out, io.TeeReader(resp.Body, pw)); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// progressWriter reports bytes written so far as they're copied, without
+// pulling in an external progress-bar dependency.
+type progressWriter struct {
+	label   string
+	total   int64
+	written int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.total > 0 {
+		fmt.Printf("\r%s: %d%%", p.label, p.written*100/p.total)
+	} else {
+		fmt.Printf("\r%s: %d bytes", p.label, p.written)
+	}
+	return len(b), nil
+}
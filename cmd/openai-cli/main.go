@@ -1,21 +1,17 @@
 package main
 
 import (
-	"bytes"
-	"context"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"github.com/sashabaranov/go-openai"
-	"github.com/spf13/cobra"
-	"image"
-	"image/png"
-	"io/ioutil"
 	"log"
 	"os"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
 )
 
 var openaiKey string
+var openaiBaseURL string
+var dialogueStream bool
+var codeGenerationStream bool
 
 func main() {
 	rootCmd := &cobra.Command{
@@ -23,14 +19,15 @@ func main() {
 		Short: "A command-line tool for OpenAI API",
 	}
 	dialogueCmd := &cobra.Command{
-		Use:   "dialogue",
-		Short: "Generate text using OpenAI's GPT-3 language model",
+		Use:   "dialogue [prompt]",
+		Short: "Chat with an OpenAI model, continuing the saved conversation history",
+		Args:  cobra.ExactArgs(1),
 		Run:   dialogue,
 	}
 
 	imageRecognitionCmd := &cobra.Command{
 		Use:   "image-recognition [filename]",
-		Short: "Describe an image using OpenAI's DALL-E image recognition model",
+		Short: "Describe an image using an OpenAI vision-capable chat model",
 		Args:  cobra.ExactArgs(1),
 		Run:   imageRecognition,
 	}
@@ -49,257 +46,163 @@ func main() {
 		Run:   imageEditing,
 	}
 
+	imageVariationCmd := &cobra.Command{
+		Use:   "image-variation [file]",
+		Short: "Generate a variation of an image using OpenAI's DALL-E model",
+		Args:  cobra.ExactArgs(1),
+		Run:   imageVariation,
+	}
+
 	audioGenerationCmd := &cobra.Command{
 		Use:   "audio-generation [text] [output file]",
-		Short: "Generate audio using OpenAI's Jukebox music model",
+		Short: "Generate speech audio from text using OpenAI's text-to-speech model",
 		Args:  cobra.ExactArgs(2),
 		Run:   audioGeneration,
 	}
 
 	audioTranscriptionCmd := &cobra.Command{
 		Use:   "audio-transcription [filename]",
-		Short: "Transcribe speech from an audio file using OpenAI's GPT-3 language model",
+		Short: "Transcribe (or translate) speech from an audio file using OpenAI's Whisper model",
 		Args:  cobra.ExactArgs(1),
 		Run:   audioTranscription,
 	}
 
 	codeGenerationCmd := &cobra.Command{
 		Use:   "code-generation [prompt]",
-		Short: "Generate code using OpenAI's Codex model",
+		Short: "Generate code using an OpenAI chat model",
 		Args:  cobra.ExactArgs(1),
 		Run:   codeGeneration,
 	}
 
+	embedCmd := &cobra.Command{
+		Use:   "embed [file or directory]",
+		Short: "Chunk, embed, and persist text for later retrieval with ask",
+		Args:  cobra.ExactArgs(1),
+		Run:   embedFiles,
+	}
+
+	askCmd := &cobra.Command{
+		Use:   "ask [question]",
+		Short: "Answer a question using the chunks embedded by embed",
+		Args:  cobra.ExactArgs(1),
+		Run:   ask,
+	}
+
+	providersCmd := &cobra.Command{
+		Use:   "providers",
+		Short: "List and manage per-command routing in ~/.openai-cli/config.yaml",
+	}
+	providersListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured providers",
+		Args:  cobra.NoArgs,
+		Run:   providersList,
+	}
+	providersSetCmd := &cobra.Command{
+		Use:   "set [command]",
+		Short: "Add or update the base URL, API key, and/or model routed to a command",
+		Args:  cobra.ExactArgs(1),
+		Run:   providersSet,
+	}
+	providersRemoveCmd := &cobra.Command{
+		Use:   "remove [command]",
+		Short: "Remove a provider entry",
+		Args:  cobra.ExactArgs(1),
+		Run:   providersRemove,
+	}
+	providersSetCmd.Flags().StringVar(&providerBaseURL, "base-url", "", "OpenAI-compatible base URL to route this command to")
+	providersSetCmd.Flags().StringVar(&providerAPIKey, "api-key", "", "API key to use for this command")
+	providersSetCmd.Flags().StringVar(&providerModel, "model", "", "default model to use for this command")
+	providersCmd.AddCommand(providersListCmd)
+	providersCmd.AddCommand(providersSetCmd)
+	providersCmd.AddCommand(providersRemoveCmd)
+
+	dialogueCmd.Flags().BoolVar(&dialogueStream, "stream", false, "stream tokens to stdout as they arrive instead of waiting for the full response")
+	dialogueCmd.Flags().StringVar(&dialogueModel, "model", "gpt-4o", "chat model to use")
+	dialogueCmd.Flags().StringVar(&dialogueSystem, "system", "", "system prompt to prepend when starting a new conversation")
+	dialogueCmd.Flags().BoolVar(&dialogueResetHistory, "reset", false, "start a new conversation instead of continuing the saved history")
+	dialogueCmd.Flags().StringVar(&dialogueTools, "tools", "", "path to a JSON manifest of local tools the model may call")
+	dialogueCmd.Flags().IntVar(&dialogueMaxToolIterations, "max-tool-iterations", 8, "maximum number of tool-call round trips before giving up")
+	dialogueCmd.Flags().BoolVar(&dialogueAllowBuiltinTools, "allow-builtin-tools", false, "enable the built-in shell, http_get, and read_file tools (dangerous: lets the model run commands, fetch URLs, and read files on this machine)")
+	dialogueCmd.Flags().BoolVar(&dialogueYes, "yes", false, "run every tool call without asking for y/N confirmation first")
+
+	codeGenerationCmd.Flags().BoolVar(&codeGenerationStream, "stream", false, "stream tokens to stdout as they arrive instead of waiting for the full response")
+	codeGenerationCmd.Flags().StringVar(&codeGenerationModel, "model", "gpt-4o", "chat model to use")
+	codeGenerationCmd.Flags().StringVar(&codeGenerationSystem, "system", "", "system prompt to steer code generation")
+
+	imageRecognitionCmd.Flags().StringVar(&imageRecognitionModel, "model", "gpt-4o", "vision-capable chat model to use")
+	imageRecognitionCmd.Flags().StringVar(&imageRecognitionPrompt, "prompt", "Describe this image in detail.", "instruction sent alongside the image")
+
+	imageGenerationCmd.Flags().StringVar(&imageGenModel, "model", "dall-e-2", "image model to use (dall-e-2, dall-e-3)")
+	imageGenerationCmd.Flags().StringVar(&imageGenSize, "size", "1024x1024", "image size, valid values depend on --model")
+	imageGenerationCmd.Flags().StringVar(&imageGenQuality, "quality", "standard", "image quality for dall-e-3 (standard, hd)")
+	imageGenerationCmd.Flags().StringVar(&imageGenStyle, "style", "vivid", "image style for dall-e-3 (vivid, natural)")
+	imageGenerationCmd.Flags().IntVar(&imageGenN, "n", 1, "number of images to generate")
+	imageGenerationCmd.Flags().StringVar(&imageGenResponseFormat, "response-format", "b64_json", "response format (b64_json, url); url downloads the image to disk with a progress bar")
+
+	imageEditingCmd.Flags().StringVar(&imageEditMask, "mask", "", "optional RGBA PNG mask; transparent regions mark the editable area")
+
+	audioGenerationCmd.Flags().StringVar(&audioVoice, "voice", "alloy", "voice to use (alloy, echo, fable, onyx, nova, shimmer)")
+	audioGenerationCmd.Flags().Float64Var(&audioSpeed, "speed", 1.0, "playback speed, from 0.25 to 4.0")
+	audioGenerationCmd.Flags().StringVar(&audioFormat, "format", "mp3", "audio format (mp3, opus, aac, flac, wav, pcm)")
+
+	audioTranscriptionCmd.Flags().StringVar(&transcriptionFormat, "format", "verbose_json", "response format (json, text, srt, verbose_json, vtt)")
+	audioTranscriptionCmd.Flags().StringVar(&transcriptionTimestampGranularities, "timestamp-granularities", "", "comma-separated timestamp granularities (segment,word); only honored with --format verbose_json")
+	audioTranscriptionCmd.Flags().BoolVar(&transcriptionTranslate, "translate", false, "translate non-English audio into English instead of transcribing in the source language")
+	audioTranscriptionCmd.Flags().StringVar(&transcriptionLanguage, "language", "", "ISO-639-1 language of the source audio (transcription only; omit to auto-detect)")
+	audioTranscriptionCmd.Flags().StringVar(&transcriptionPrompt, "prompt", "", "optional text to guide the model's style or continue a prior segment")
+
+	embedCmd.Flags().StringVar(&embedModel, "model", "text-embedding-3-small", "embedding model to use")
+	embedCmd.Flags().IntVar(&embedDim, "dim", 0, "truncate text-embedding-3-* vectors to this many dimensions (0 keeps the model default)")
+	embedCmd.Flags().StringVar(&embedStore, "store", "", "path to the SQLite vector store (defaults to ~/.openai-cli/vectors.db)")
+	embedCmd.Flags().IntVar(&embedChunkSize, "chunk-size", 512, "chunk size in tokens")
+	embedCmd.Flags().IntVar(&embedChunkOverlap, "chunk-overlap", 64, "token overlap between consecutive chunks")
+
+	askCmd.Flags().StringVar(&askModel, "model", "text-embedding-3-small", "embedding model to use for the query")
+	askCmd.Flags().IntVar(&askDim, "dim", 0, "truncate text-embedding-3-* vectors to this many dimensions (0 keeps the model default)")
+	askCmd.Flags().StringVar(&askStore, "store", "", "path to the SQLite vector store (defaults to ~/.openai-cli/vectors.db)")
+	askCmd.Flags().IntVar(&askTopK, "top-k", 4, "number of chunks to retrieve as context")
+
 	rootCmd.PersistentFlags().StringVar(&openaiKey, "key", "", "OpenAI API key (can also be set using OPENAI_API_KEY environment variable)")
+	rootCmd.PersistentFlags().StringVar(&openaiBaseURL, "base-url", "", "OpenAI-compatible base URL, overriding any provider configured in ~/.openai-cli/config.yaml")
 
 	rootCmd.AddCommand(dialogueCmd)
 	rootCmd.AddCommand(imageRecognitionCmd)
 	rootCmd.AddCommand(imageGenerationCmd)
 	rootCmd.AddCommand(imageEditingCmd)
+	rootCmd.AddCommand(imageVariationCmd)
 	rootCmd.AddCommand(audioGenerationCmd)
 	rootCmd.AddCommand(audioTranscriptionCmd)
 	rootCmd.AddCommand(codeGenerationCmd)
+	rootCmd.AddCommand(providersCmd)
+	rootCmd.AddCommand(embedCmd)
+	rootCmd.AddCommand(askCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
 
 }
-func getClient() *openai.Client {
+func getClient(cmd *cobra.Command) *openai.Client {
+	provider := resolveProvider(cmd)
+
 	key := openaiKey
 	if key == "" {
-		key = os.Getenv("OPENAI_API_KEY")
+		key = provider.APIKey
 	}
 	if key == "" {
-		log.Fatal("OpenAI API key not found. Set it using --key or OPENAI_API_KEY environment variable")
-	}
-	return openai.NewClient(key)
-}
-func strToImageBytes(input string) ([]byte, error) {
-	// Example JSON input with base64-encoded image data
-
-	// Decode the JSON input into an ImageData object
-	type ImageData struct {
-		Data string `json:"data"`
-	}
-	var imageData ImageData
-	err := json.Unmarshal([]byte(input), &imageData)
-	if err != nil {
-		return nil, err
-	}
-
-	// Decode the base64-encoded image data into a byte slice
-	decoded, err := base64.StdEncoding.DecodeString(imageData.Data)
-	if err != nil {
-		return nil, err
-	}
-
-	// Decode the byte slice into an image object
-	img, _, err := image.Decode(bytes.NewReader(decoded))
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert the image object to a byte slice
-	var buf bytes.Buffer
-	err = png.Encode(&buf, img)
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
-}
-func dialogue(cmd *cobra.Command, args []string) {
-	client := getClient()
-	prompt := args[0]
-
-	completion, err := client.CreateCompletion(context.Background(), openai.CompletionRequest{
-		Prompt:    prompt,
-		Model:     "text-davinci-003",
-		MaxTokens: 5000,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	for _, choice := range completion.Choices {
-		fmt.Println(choice.Text)
-	}
-
-}
-func imageRecognition(cmd *cobra.Command, args []string) {
-	client := getClient()
-	filename := args[0]
-
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	imageBase64 := base64.StdEncoding.EncodeToString(data)
-
-	imageRecogRequest := openai.CompletionRequest{
-		Prompt:      fmt.Sprintf("描述这个图片: %s", imageBase64),
-		Model:       "image-alpha-001",
-		MaxTokens:   50,
-		Temperature: 0.5,
-		N:           1,
-	}
-
-	result, err := client.CreateCompletion(context.Background(), imageRecogRequest)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println(result.Choices[0].Text)
-
-}
-func imageGeneration(cmd *cobra.Command, args []string) {
-	client := getClient()
-	prompt := args[0]
-
-	result, err := client.CreateImage(context.Background(), openai.ImageRequest{
-		Prompt:         prompt,
-		N:              1,
-		Size:           openai.CreateImageSize256x256,
-		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
-		User:           "Developer",
-	})
-	if err != nil {
-		log.Fatal("no result, no image data")
-	}
-
-	if len(result.Data) == 0 {
-		log.Fatal()
-	}
-
-	imageBytes, err := strToImageBytes(result.Data[0].B64JSON)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = ioutil.WriteFile("output.png", imageBytes, 0644)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Println("Image saved to output.png")
-
-}
-func imageEditing(cmd *cobra.Command, args []string) {
-	client := getClient()
-	inputFile := args[0]
-	instructions := args[1]
-	outputFile := args[2]
-
-	inputData, err := os.Open(inputFile)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer inputData.Close()
-
-	result, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
-		Image:  inputData,
-		Prompt: instructions,
-		N:      1,
-		Size:   openai.CreateImageSize256x256,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	if len(result.Data) == 0 {
-		log.Fatal("no result, no image edited")
-	}
-
-	ret, err := strToImageBytes(result.Data[0].B64JSON)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = ioutil.WriteFile(outputFile, ret, 0644)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Printf("Image saved to %s\n", outputFile)
-
-}
-func audioGeneration(cmd *cobra.Command, args []string) {
-	client := getClient()
-	text := args[0]
-	outputFile := args[1]
-
-	result, err := client.CreateCompletion(context.Background(), openai.CompletionRequest{
-		Prompt:      text,
-		Model:       "whisper-3",
-		N:           1,
-		Temperature: 0.5,
-		MaxTokens:   1024,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = ioutil.WriteFile(outputFile, []byte(result.Choices[0].Text), 0644)
-	if err != nil {
-		log.Fatal(err)
+		key = os.Getenv("OPENAI_API_KEY")
 	}
-
-	fmt.Printf("Audio saved to %s\n", outputFile)
-
-}
-func audioTranscription(cmd *cobra.Command, args []string) {
-	client := getClient()
-	filename := args[0]
-
-	result, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
-		FilePath:    filename,
-		Model:       "whisper-3",
-		Prompt:      "用简体中文",
-		Temperature: 0.5,
-		Language:    "zh",
-	})
-	if err != nil {
-		log.Fatal(err)
+	if key == "" {
+		log.Fatal("OpenAI API key not found. Set it using --key, OPENAI_API_KEY, or a provider entry in ~/.openai-cli/config.yaml")
 	}
 
-	fmt.Println(result.Text)
-
-}
-func codeGeneration(cmd *cobra.Command, args []string) {
-	client := getClient()
-	prompt := args[0]
-
-	result, err := client.CreateCompletion(context.Background(), openai.CompletionRequest{
-		Prompt:      prompt,
-		MaxTokens:   5000,
-		Model:       "davinci-codex-002",
-		N:           1,
-		Temperature: 0.5,
-	})
-	if err != nil {
-		log.Fatal(err)
+	clientConfig := openai.DefaultConfig(key)
+	switch {
+	case openaiBaseURL != "":
+		clientConfig.BaseURL = openaiBaseURL
+	case provider.BaseURL != "":
+		clientConfig.BaseURL = provider.BaseURL
 	}
-
-	fmt.Println(result.Choices[0].Text)
-
+	return openai.NewClientWithConfig(clientConfig)
 }
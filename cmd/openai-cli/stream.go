@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// withInterrupt wraps parent with a context that is canceled as soon as the
+// process receives SIGINT (Ctrl+C), so an in-flight stream can unwind
+// cleanly instead of leaving the terminal mid-token.
+func withInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
+	}()
+	return ctx, cancel
+}
+
+// streamChatCompletion reads a chat completion SSE stream, writing each
+// delta's content to stdout as it arrives and returning the accumulated
+// reply once the server sends [DONE], the stream errors out, or the
+// context is canceled.
+func streamChatCompletion(client *openai.Client, req openai.ChatCompletionRequest) (string, error) {
+	ctx, cancel := withInterrupt(context.Background())
+	defer cancel()
+
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var reply strings.Builder
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return reply.String(), nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Println()
+				return reply.String(), nil
+			}
+			return reply.String(), err
+		}
+		for _, choice := range resp.Choices {
+			fmt.Print(choice.Delta.Content)
+			reply.WriteString(choice.Delta.Content)
+		}
+	}
+}
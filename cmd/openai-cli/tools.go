@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// toolManifestEntry is one entry of the JSON array loaded via --tools: a
+// function signature for the model plus the local command that implements
+// it.
+type toolManifestEntry struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+	Command     []string        `json:"command"`
+}
+
+// toolRegistry maps the tool definitions sent to the model to the local
+// Go or subprocess handlers that actually execute them.
+type toolRegistry struct {
+	defs     []openai.Tool
+	handlers map[string]func(args string) (string, error)
+}
+
+// newToolRegistry returns an empty registry, optionally pre-populated with
+// the built-in shell, http_get, and read_file tools. Those three can run
+// arbitrary commands, fetch arbitrary URLs, and read arbitrary local files
+// at the model's direction, so callers must opt in explicitly.
+func newToolRegistry(allowBuiltins bool) *toolRegistry {
+	r := &toolRegistry{handlers: map[string]func(string) (string, error){}}
+	if !allowBuiltins {
+		return r
+	}
+
+	r.register(openai.Tool{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "shell",
+			Description: "Run a shell command locally and return its combined stdout/stderr.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {"command": {"type": "string", "description": "the command to run via sh -c"}},
+				"required": ["command"]
+			}`),
+		},
+	}, shellTool)
+
+	r.register(openai.Tool{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "http_get",
+			Description: "Fetch a URL over HTTP GET and return the response body.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {"url": {"type": "string"}},
+				"required": ["url"]
+			}`),
+		},
+	}, httpGetTool)
+
+	r.register(openai.Tool{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "read_file",
+			Description: "Read a local file and return its contents.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {"path": {"type": "string"}},
+				"required": ["path"]
+			}`),
+		},
+	}, readFileTool)
+
+	return r
+}
+
+func (r *toolRegistry) register(def openai.Tool, handler func(args string) (string, error)) {
+	r.defs = append(r.defs, def)
+	r.handlers[def.Function.Name] = handler
+}
+
+// loadManifest adds the tools described in a --tools JSON file, each backed
+// by the local command it configures.
+func (r *toolRegistry) loadManifest(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var entries []toolManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry := entry
+		r.register(openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        entry.Name,
+				Description: entry.Description,
+				Parameters:  entry.Parameters,
+			},
+		}, execCommandTool(entry.Command))
+	}
+	return nil
+}
+
+// call invokes the named tool with its raw JSON arguments.
+func (r *toolRegistry) call(name, args string) (string, error) {
+	handler, ok := r.handlers[name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	return handler(args)
+}
+
+// confirmToolCall prints the tool call the model is about to make and asks
+// the user to approve it, so a prompt-injected page or manifest tool can't
+// run locally without a human in the loop.
+func confirmToolCall(name, args string) bool {
+	fmt.Printf("Model wants to call %s(%s)\nAllow? [y/N] ", name, args)
+	reader := bufio.NewReader(os.Stdin)
+	line, _ := reader.ReadString('\n')
+	switch strings.TrimSpace(strings.ToLower(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// execCommandTool runs a manifest-configured local command, writing the raw
+// JSON arguments to its stdin and returning its stdout as the tool result.
+func execCommandTool(command []string) func(args string) (string, error) {
+	return func(args string) (string, error) {
+		if len(command) == 0 {
+			return "", fmt.Errorf("tool has no command configured")
+		}
+
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(args)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s: %w: %s", command[0], err, stderr.String())
+		}
+		return stdout.String(), nil
+	}
+}
+
+func shellTool(args string) (string, error) {
+	var params struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", err
+	}
+
+	out, err := exec.Command("sh", "-c", params.Command).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%w: %s", err, out)
+	}
+	return string(out), nil
+}
+
+func httpGetTool(args string) (string, error) {
+	var params struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", err
+	}
+
+	resp, err := http.Get(params.URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func readFileTool(args string) (string, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(params.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}